@@ -0,0 +1,210 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMigrationLocked is returned by MigrateWithLock when the lock couldn't be acquired
+// before LockOptions.Timeout elapsed.
+var ErrMigrationLocked = fmt.Errorf("migration is locked by another process")
+
+// LockOptions configures MigrateWithLock.
+type LockOptions struct {
+	// Timeout is the maximum time to wait for the lock. Zero means "try once, don't wait".
+	Timeout time.Duration
+
+	// PollInterval is how often to retry acquiring the lock while waiting.
+	// Defaults to 200ms.
+	PollInterval time.Duration
+}
+
+// lockSave is the companion row used to serialize concurrent Migrate calls on databases
+// that don't offer a native advisory lock.
+type lockSave struct {
+	ID       uint `gorm:"primarykey"`
+	LockedAt time.Time
+}
+
+func (lockSave) TableName() string {
+	return "gorm_migration_locks"
+}
+
+// advisoryLockKey identifies the gorm-migrate advisory lock among any others the
+// application might take on the same connection.
+const advisoryLockKey = 615089215
+
+// mysqlLockName is the MySQL GET_LOCK/RELEASE_LOCK counterpart of advisoryLockKey.
+const mysqlLockName = "gorm_migrate"
+
+// MigrateWithLock behaves like Migrate but first acquires a cross-process lock, so that
+// concurrent deployers (e.g. a Kubernetes rolling deploy) don't race on applying the same
+// migrations. Postgres and MySQL use the database's native advisory lock; other dialects
+// fall back to a companion "gorm_migration_locks" table.
+//
+// If the lock can't be acquired within options.Timeout, ErrMigrationLocked is returned.
+func MigrateWithLock(
+	ctx context.Context, db *gorm.DB, steps Migrations, direction int, options LockOptions,
+) (int, error) {
+	return MigrateWithLockAndOptions(ctx, db, steps, direction, options, Options{UseTransaction: true})
+}
+
+// MigrateWithLockAndOptions behaves like MigrateWithLock but takes a full Options, so a
+// custom TableName, InitSchema, Observer, or UseTransaction can be combined with the lock
+// instead of being limited to Migrate's defaults.
+//
+// If the lock can't be acquired within lockOptions.Timeout, ErrMigrationLocked is returned.
+func MigrateWithLockAndOptions(
+	ctx context.Context, db *gorm.DB, steps Migrations, direction int, lockOptions LockOptions, options Options,
+) (int, error) {
+	if lockOptions.PollInterval <= 0 {
+		lockOptions.PollInterval = 200 * time.Millisecond
+	}
+
+	l, err := newLocker(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+
+	if err := acquireLock(ctx, l, lockOptions); err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = l.Unlock(ctx)
+	}()
+
+	return MigrateWithOptions(db, steps, direction, options)
+}
+
+func acquireLock(ctx context.Context, l locker, options LockOptions) error {
+	deadline := time.Now().Add(options.Timeout)
+
+	for {
+		acquired, err := l.TryLock(ctx)
+		if err != nil {
+			return err
+		}
+
+		if acquired {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return ErrMigrationLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(options.PollInterval):
+		}
+	}
+}
+
+// locker abstracts the mutual-exclusion mechanism used around a migration run.
+type locker interface {
+	TryLock(ctx context.Context) (bool, error)
+	Unlock(ctx context.Context) error
+
+	// Close releases any resource (e.g. a pinned connection) held by the locker. It must
+	// be called once the locker is no longer needed, whether or not the lock was acquired.
+	Close() error
+}
+
+func newLocker(ctx context.Context, db *gorm.DB) (locker, error) {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return newConnLocker(ctx, db, "SELECT pg_try_advisory_lock($1)", "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+	case "mysql":
+		return newConnLocker(ctx, db, "SELECT GET_LOCK(?, 0)", "SELECT RELEASE_LOCK(?)", mysqlLockName)
+	default:
+		return &tableLocker{db: db}, nil
+	}
+}
+
+// connLocker implements session-scoped advisory locks (Postgres's pg_advisory_lock,
+// MySQL's GET_LOCK) that can only be released from the connection that acquired them.
+// It pins a single *sql.Conn out of the pool for its entire lifetime so TryLock and
+// Unlock are guaranteed to run on the same session; letting gorm pick a connection per
+// call risks Unlock landing on a different connection, silently failing to release the
+// lock (advisory-unlock functions return false/0 instead of erroring) and leaving every
+// other deployer blocked until the leaked connection is evicted from the pool.
+type connLocker struct {
+	conn       *sql.Conn
+	lockQuery  string
+	unlockStmt string
+	key        interface{}
+}
+
+func newConnLocker(ctx context.Context, db *gorm.DB, lockQuery, unlockStmt string, key interface{}) (*connLocker, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connLocker{conn: conn, lockQuery: lockQuery, unlockStmt: unlockStmt, key: key}, nil
+}
+
+func (l *connLocker) TryLock(ctx context.Context) (bool, error) {
+	var locked bool
+
+	if err := l.conn.QueryRowContext(ctx, l.lockQuery, l.key).Scan(&locked); err != nil {
+		return false, err
+	}
+
+	return locked, nil
+}
+
+func (l *connLocker) Unlock(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, l.unlockStmt, l.key)
+
+	return err
+}
+
+func (l *connLocker) Close() error {
+	return l.conn.Close()
+}
+
+// tableLocker emulates an advisory lock with a unique row insert, for dialects without a
+// native one (e.g. sqlite, sqlserver). Unlike connLocker, its state lives in a table row
+// rather than a session, so it doesn't need a pinned connection.
+type tableLocker struct {
+	db *gorm.DB
+}
+
+func (l *tableLocker) TryLock(ctx context.Context) (bool, error) {
+	db := l.db.WithContext(ctx)
+
+	if err := db.AutoMigrate(&lockSave{}); err != nil {
+		return false, err
+	}
+
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lockSave{ID: 1, LockedAt: time.Now().UTC()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (l *tableLocker) Unlock(ctx context.Context) error {
+	return l.db.WithContext(ctx).Delete(&lockSave{}, 1).Error
+}
+
+func (l *tableLocker) Close() error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestConnLockerPinsSingleConnection guards against the bug where TryLock and Unlock ran
+// on independent pooled connections: session-scoped locks (pg_advisory_lock, GET_LOCK)
+// can only be released from the connection that acquired them, so a fresh pooled
+// connection per call would silently leak the lock. SQLite's TEMP tables are
+// connection-scoped in exactly the same way, so creating one through the locker's pinned
+// connection and reading it back proves TryLock/Unlock share a single session.
+func TestConnLockerPinsSingleConnection(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Couldn't open DB: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Couldn't get the underlying sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(5)
+
+	ctx := context.Background()
+
+	l, err := newConnLocker(ctx, db, "SELECT 1", "SELECT 1", 0)
+	if err != nil {
+		t.Fatalf("Couldn't create the locker: %v", err)
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+
+	if _, err := l.conn.ExecContext(ctx, "CREATE TEMP TABLE lock_probe(id INTEGER)"); err != nil {
+		t.Fatalf("Couldn't create the temp table: %v", err)
+	}
+
+	var count int
+	if err := l.conn.QueryRowContext(ctx, "SELECT count(*) FROM lock_probe").Scan(&count); err != nil {
+		t.Fatalf("Temp table not visible on the pinned connection, TryLock/Unlock would land on different sessions: %v", err)
+	}
+}
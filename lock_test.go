@@ -0,0 +1,92 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+func TestMigrateWithLock(t *testing.T) {
+	steps := []*migrate.MigrationStep{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	if nb, err := migrate.MigrateWithLock(
+		context.Background(), db, steps, migrate.UpFull, migrate.LockOptions{},
+	); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	} else if nb != 1 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+}
+
+func TestMigrateWithLockContention(t *testing.T) {
+	steps := []*migrate.MigrationStep{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	// Simulate a lock already held by another process.
+	type lockRow struct {
+		ID       uint `gorm:"primarykey"`
+		LockedAt time.Time
+	}
+
+	if err := db.Table("gorm_migration_locks").AutoMigrate(&lockRow{}); err != nil {
+		t.Fatalf("Couldn't prepare the lock table: %v", err)
+	}
+	if err := db.Table("gorm_migration_locks").Create(&lockRow{ID: 1, LockedAt: time.Now()}).Error; err != nil {
+		t.Fatalf("Couldn't hold the lock: %v", err)
+	}
+
+	_, err := migrate.MigrateWithLock(
+		context.Background(), db, steps, migrate.UpFull,
+		migrate.LockOptions{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond},
+	)
+
+	if !errors.Is(err, migrate.ErrMigrationLocked) {
+		t.Fatalf("Expected ErrMigrationLocked, got: %v", err)
+	}
+}
+
+func TestMigrateWithLockAndOptions(t *testing.T) {
+	steps := []*migrate.MigrationStep{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	options := migrate.Options{TableName: "custom_migrations", UseTransaction: false}
+
+	if nb, err := migrate.MigrateWithLockAndOptions(
+		context.Background(), db, steps, migrate.UpFull, migrate.LockOptions{}, options,
+	); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	} else if nb != 1 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+
+	if !db.Migrator().HasTable("custom_migrations") {
+		t.Fatal("Custom migrations table should have been created")
+	}
+}
@@ -19,6 +19,28 @@ type MigrationStep struct {
 	Name string          // Name is the name of the migration
 	Up   MigrationMethod // Up is the upgrade migration method
 	Down MigrationMethod // Down is the downgrade migration method
+
+	// BeforeUp, AfterUp, BeforeDown and AfterDown are optional lifecycle hooks run right
+	// before/after Up/Down, in the same transaction as the step. Returning an error fails the
+	// step exactly as if Up/Down itself had failed: with Options.UseTransaction the whole run
+	// is rolled back; otherwise only the step's own transaction/savepoint is rolled back, and
+	// a non-transactional step's changes are not rolled back at all.
+	BeforeUp   MigrationMethod
+	AfterUp    MigrationMethod
+	BeforeDown MigrationMethod
+	AfterDown  MigrationMethod
+
+	// Transactional controls whether this step (and its hooks) runs inside a transaction
+	// or savepoint of its own. It defaults to true when left nil, like stepSave.MigrationTime
+	// defaults to "not applied" when nil. Set it to a false pointer for statements that can't
+	// run inside a transaction, such as "CREATE INDEX CONCURRENTLY" on Postgres or most DDL
+	// on MySQL. Only takes effect when Options.UseTransaction is false: see MigrateWithOptions.
+	Transactional *bool
+}
+
+// stepIsTransactional reports whether step should run inside its own transaction/savepoint.
+func stepIsTransactional(step *MigrationStep) bool {
+	return step.Transactional == nil || *step.Transactional
 }
 
 // Migrations contains the migration steps we want to apply.
@@ -45,6 +67,9 @@ const (
 
 	// StepIssueBadlyOrdered means the issue is badly ordered.
 	StepIssueBadlyOrdered = "badly_ordered"
+
+	// StepIssueUnknown means the migrations table references a step unknown to the caller.
+	StepIssueUnknown = "unknown"
 )
 
 func (e *ErrBadMigration) Error() string {
@@ -63,9 +88,12 @@ var (
 	ErrInconsistentSteps = fmt.Errorf("inconsistent steps")
 )
 
-func getLastAppliedMigration(db *gorm.DB) (*stepSave, error) {
+func getLastAppliedMigration(db *gorm.DB, tableName string) (*stepSave, error) {
 	var step stepSave
-	err := db.Order("name desc").Where("migration_time is not null").First(&step).Error
+	err := db.Table(tableName).
+		Order("name desc").
+		Where("migration_time is not null AND name <> ?", schemaInitName).
+		First(&step).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -78,9 +106,9 @@ func getLastAppliedMigration(db *gorm.DB) (*stepSave, error) {
 	return &step, err
 }
 
-func getMigration(db *gorm.DB, name string) (*stepSave, error) {
+func getMigration(db *gorm.DB, tableName string, name string) (*stepSave, error) {
 	stepSave := &stepSave{Name: name}
-	err := db.Where(stepSave).First(stepSave).Error
+	err := db.Table(tableName).Where(stepSave).First(stepSave).Error
 
 	if err == nil {
 		return stepSave, nil
@@ -93,13 +121,21 @@ func getMigration(db *gorm.DB, name string) (*stepSave, error) {
 	return nil, err
 }
 
-func saveMigration(db *gorm.DB, step *stepSave) error {
-	return db.Clauses(clause.OnConflict{
+func saveMigration(db *gorm.DB, tableName string, step *stepSave) error {
+	return db.Table(tableName).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "name"}},
 		DoUpdates: clause.AssignmentColumns([]string{"migration_time"}),
 	}).Create(step).Error
 }
 
+func countMigrations(db *gorm.DB, tableName string) (int64, error) {
+	var count int64
+
+	err := db.Table(tableName).Count(&count).Error
+
+	return count, err
+}
+
 func checkMigrations(steps Migrations) error {
 	name := ""
 	for _, s := range steps {
@@ -171,22 +207,100 @@ Loop:
 	return nextSteps
 }
 
+// InitSchemaFunc builds the current schema from scratch. It's meant to replace the whole
+// chain of incremental steps when bootstrapping a fresh database.
+type InitSchemaFunc func(db *gorm.DB) error
+
+// Options configures how Migrate/MigrateWithOptions behaves.
+type Options struct {
+	// InitSchema is run instead of the incremental steps when upgrading while the migrations
+	// table is empty; a downgrade never runs it.
+	// Every step is then marked as applied without running its Up method, so the incremental
+	// history keeps working for databases that already went through it.
+	InitSchema InitSchemaFunc
+
+	// TableName overrides the table used to track applied migrations.
+	// Defaults to "gorm_migrations" when left empty.
+	TableName string
+
+	// UseTransaction wraps the whole migration run in a single transaction: either every
+	// step applies, or none does, and MigrationStep.Transactional is ignored since
+	// everything already shares one transaction.
+	//
+	// Leave it to false to apply each step on its own: a Transactional step (the default)
+	// still gets its own transaction/savepoint and rolls back on failure without touching
+	// previously-applied steps, while a non-transactional one runs directly against the
+	// database - required for statements like "CREATE INDEX CONCURRENTLY" on Postgres, and
+	// for MySQL where DDL auto-commits anyway. Concurrent deployers still need mutual
+	// exclusion in that mode: use MigrateWithLockAndOptions with UseTransaction: false
+	// rather than the plain MigrateWithLock, which always runs with UseTransaction: true.
+	UseTransaction bool
+
+	// ValidateUnknownMigrations makes the run fail if the migrations table references steps
+	// that are not present in the provided steps.
+	ValidateUnknownMigrations bool
+
+	// IgnoreUnknown makes StatusWithOptions omit migrations found in the database but missing from the
+	// provided steps, instead of reporting them as StepStatus.Known == false.
+	IgnoreUnknown bool
+
+	// Observer, if set, is notified of lifecycle events around the run and each of its steps.
+	Observer Observer
+}
+
+// schemaInitName is the synthetic step name recorded when InitSchema is used.
+const schemaInitName = "SCHEMA_INIT"
+
+// defaultTableName is the table used to track applied migrations when Options.TableName is empty.
+const defaultTableName = "gorm_migrations"
+
 // Migrate handles all the step of the migration steps.
 func Migrate(db *gorm.DB, steps Migrations, direction int) (int, error) {
+	return MigrateWithOptions(db, steps, direction, Options{UseTransaction: true})
+}
+
+// MigrateWithOptions behaves like Migrate but lets the caller pick an InitSchema fast-path,
+// a custom migrations table name, whether to wrap the run in a transaction, and whether to
+// fail on migrations unknown to the caller.
+func MigrateWithOptions(db *gorm.DB, steps Migrations, direction int, options Options) (int, error) {
+	tableName := options.TableName
+	if tableName == "" {
+		tableName = defaultTableName
+	}
+
 	nbApplied := 0
 
-	return nbApplied, db.Transaction(func(db *gorm.DB) error {
+	run := func(db *gorm.DB) error {
 		if err := checkMigrations(steps); err != nil {
 			return fmt.Errorf("bad migration: %w", err)
 		}
 		if direction == 0 {
 			return ErrBadDirection
 		}
-		lastMigrationName := ""
-		if err := prepareMigrationTables(db); err != nil {
+		if err := prepareMigrationTables(db, tableName); err != nil {
+			return err
+		}
+
+		if options.ValidateUnknownMigrations {
+			if err := validateKnownMigrations(db, tableName, steps); err != nil {
+				return err
+			}
+		}
+
+		nbExisting, err := countMigrations(db, tableName)
+		if err != nil {
+			return err
+		}
+
+		if nbExisting == 0 && options.InitSchema != nil && direction > 0 {
+			nb, err := initSchema(db, tableName, steps, options.InitSchema)
+			nbApplied = nb
+
 			return err
 		}
-		if lastMigration, err := getLastAppliedMigration(db); err != nil {
+
+		lastMigrationName := ""
+		if lastMigration, err := getLastAppliedMigration(db, tableName); err != nil {
 			return err
 		} else if lastMigration != nil {
 			lastMigrationName = lastMigration.Name
@@ -199,13 +313,74 @@ func Migrate(db *gorm.DB, steps Migrations, direction int) (int, error) {
 
 		steps = getSteps(steps, lastMigrationName, direction)
 
-		nb, err := applyMigration(db, steps, direction > 0)
+		nb, err := applyMigration(db, tableName, steps, direction > 0, options.Observer, !options.UseTransaction)
 		nbApplied = nb
 		if err != nil {
 			return fmt.Errorf("couldn't apply migrations: %w", err)
 		}
 		return nil
-	})
+	}
+
+	var err error
+	if options.UseTransaction {
+		err = db.Transaction(run)
+	} else {
+		err = run(db)
+	}
+
+	if options.Observer != nil {
+		options.Observer.OnBatchEnd(nbApplied, err)
+	}
+
+	return nbApplied, err
+}
+
+// initSchema runs the InitSchema func and marks every known step as applied without running
+// their Up method, so the incremental history can resume seamlessly on the next run.
+func initSchema(db *gorm.DB, tableName string, steps Migrations, initFunc InitSchemaFunc) (int, error) {
+	if err := initFunc(db); err != nil {
+		return 0, fmt.Errorf("couldn't init schema: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	if err := saveMigration(db, tableName, &stepSave{Name: schemaInitName, MigrationTime: &now}); err != nil {
+		return 0, fmt.Errorf("couldn't save schema init: %w", err)
+	}
+
+	for _, step := range steps {
+		t := time.Now().UTC()
+		if err := saveMigration(db, tableName, &stepSave{Name: step.Name, MigrationTime: &t}); err != nil {
+			return 0, fmt.Errorf("couldn't save migration %s application: %w", step.Name, err)
+		}
+	}
+
+	return len(steps), nil
+}
+
+// validateKnownMigrations fails if the migrations table references a step that's not part
+// of steps.
+func validateKnownMigrations(db *gorm.DB, tableName string, steps Migrations) error {
+	var dbSteps []stepSave
+	if err := db.Table(tableName).Find(&dbSteps).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, dbStep := range dbSteps {
+		if dbStep.Name == schemaInitName {
+			continue
+		}
+
+		if getIndexForName(steps, dbStep.Name) == -1 {
+			return &ErrBadMigration{Name: dbStep.Name, Type: StepIssueUnknown}
+		}
+	}
+
+	return nil
 }
 
 // ValidateSteps validates that all the steps can be applied up & down
@@ -248,11 +423,13 @@ func ValidateSteps(db *gorm.DB, steps Migrations) error {
 	return nil
 }
 
-func applyMigration(db *gorm.DB, steps Migrations, up bool) (int, error) {
+func applyMigration(
+	db *gorm.DB, tableName string, steps Migrations, up bool, observer Observer, perStepTransaction bool,
+) (int, error) {
 	nb := 0
 
 	for _, step := range steps {
-		dbStep, err := getMigration(db, step.Name)
+		dbStep, err := getMigration(db, tableName, step.Name)
 		if err != nil {
 			return nb, err
 		}
@@ -261,7 +438,7 @@ func applyMigration(db *gorm.DB, steps Migrations, up bool) (int, error) {
 			dbStep = &stepSave{Name: step.Name}
 		}
 
-		var method MigrationMethod
+		var before, method, after MigrationMethod
 		var migrationTime *time.Time = nil
 
 		direction := ""
@@ -269,10 +446,10 @@ func applyMigration(db *gorm.DB, steps Migrations, up bool) (int, error) {
 			direction = "upgrade"
 			t := time.Now().UTC()
 			migrationTime = &t
-			method = step.Up
+			before, method, after = step.BeforeUp, step.Up, step.AfterUp
 		} else {
 			direction = "downgrade"
-			method = step.Down
+			before, method, after = step.BeforeDown, step.Down, step.AfterDown
 		}
 
 		db.Logger.Warn(
@@ -282,20 +459,94 @@ func applyMigration(db *gorm.DB, steps Migrations, up bool) (int, error) {
 			dbStep.Name,
 		)
 
-		if err := method(db); err != nil {
-			return nb, fmt.Errorf("couldn't apply migration %s: %w", step.Name, err)
+		if observer != nil {
+			observer.OnStepStart(step, up)
 		}
 
 		dbStep.MigrationTime = migrationTime
-		if err := saveMigration(db, dbStep); err != nil {
-			return nb, fmt.Errorf("couldn't save migration %s application: %w", step.Name, err)
+
+		start := time.Now()
+		err = runStepAndSave(db, tableName, step, dbStep, before, method, after, perStepTransaction)
+
+		if observer != nil {
+			observer.OnStepEnd(step, up, time.Since(start), err)
+		}
+
+		if err != nil {
+			return nb, fmt.Errorf("couldn't apply migration %s: %w", step.Name, err)
 		}
+
 		nb++
 	}
 
 	return nb, nil
 }
 
+// runStepAndSave runs a step's hooks/method and persists its tracking row.
+//
+// When perStepTransaction is false, the caller already wrapped the whole run in a single
+// transaction (Options.UseTransaction), so this simply runs everything against db as-is.
+//
+// When perStepTransaction is true, a transactional step (the default) gets its own
+// transaction or savepoint, committed right after its row is saved, so it doesn't depend on
+// the other steps. A non-transactional step runs directly against db instead, and its row
+// is saved in a tiny transaction of its own immediately after.
+func runStepAndSave(
+	db *gorm.DB, tableName string, step *MigrationStep, dbStep *stepSave,
+	before, method, after MigrationMethod, perStepTransaction bool,
+) error {
+	if !perStepTransaction || stepIsTransactional(step) {
+		run := func(tx *gorm.DB) error {
+			if err := runStepMethods(tx, before, method, after); err != nil {
+				return err
+			}
+
+			if err := saveMigration(tx, tableName, dbStep); err != nil {
+				return fmt.Errorf("couldn't save migration %s application: %w", step.Name, err)
+			}
+
+			return nil
+		}
+
+		if perStepTransaction {
+			return db.Transaction(run)
+		}
+
+		return run(db)
+	}
+
+	if err := runStepMethods(db, before, method, after); err != nil {
+		return err
+	}
+
+	if err := saveMigration(db, tableName, dbStep); err != nil {
+		return fmt.Errorf("couldn't save migration %s application: %w", step.Name, err)
+	}
+
+	return nil
+}
+
+// runStepMethods runs a step's before/method/after hooks, in order, stopping at the first error.
+func runStepMethods(db *gorm.DB, before MigrationMethod, method MigrationMethod, after MigrationMethod) error {
+	if before != nil {
+		if err := before(db); err != nil {
+			return fmt.Errorf("before hook: %w", err)
+		}
+	}
+
+	if err := method(db); err != nil {
+		return err
+	}
+
+	if after != nil {
+		if err := after(db); err != nil {
+			return fmt.Errorf("after hook: %w", err)
+		}
+	}
+
+	return nil
+}
+
 const (
 	// UpFull is a complete upgrade migration.
 	UpFull = 100000
@@ -307,6 +558,6 @@ const (
 	DownOne = -1
 )
 
-func prepareMigrationTables(db *gorm.DB) error {
-	return db.AutoMigrate(&stepSave{})
+func prepareMigrationTables(db *gorm.DB, tableName string) error {
+	return db.Table(tableName).AutoMigrate(&stepSave{})
 }
@@ -0,0 +1,16 @@
+package migrate
+
+import "time"
+
+// Observer receives lifecycle events around a Migrate/MigrateWithOptions run. It's set via
+// Options.Observer and is useful for emitting metrics/traces around each step.
+type Observer interface {
+	// OnStepStart is called right before a step's method (and hooks) run.
+	OnStepStart(step *MigrationStep, up bool)
+
+	// OnStepEnd is called right after a step's method (and hooks) ran, successfully or not.
+	OnStepEnd(step *MigrationStep, up bool, duration time.Duration, err error)
+
+	// OnBatchEnd is called once a whole run is done, successfully or not.
+	OnBatchEnd(nb int, err error)
+}
@@ -0,0 +1,109 @@
+package migrate_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+type recordingObserver struct {
+	starts    []string
+	ends      []string
+	batchNb   int
+	batchErr  error
+	batchDone bool
+}
+
+func (o *recordingObserver) OnStepStart(step *migrate.MigrationStep, up bool) {
+	o.starts = append(o.starts, step.Name)
+}
+
+func (o *recordingObserver) OnStepEnd(step *migrate.MigrationStep, up bool, duration time.Duration, err error) {
+	o.ends = append(o.ends, step.Name)
+}
+
+func (o *recordingObserver) OnBatchEnd(nb int, err error) {
+	o.batchNb = nb
+	o.batchErr = err
+	o.batchDone = true
+}
+
+func TestStepHooks(t *testing.T) {
+	var calls []string
+
+	steps := migrate.Migrations{
+		{
+			Name:       "000",
+			BeforeUp:   func(db *gorm.DB) error { calls = append(calls, "before"); return nil },
+			Up:         func(db *gorm.DB) error { calls = append(calls, "up"); return nil },
+			AfterUp:    func(db *gorm.DB) error { calls = append(calls, "after"); return nil },
+			BeforeDown: func(db *gorm.DB) error { return nil },
+			Down:       func(db *gorm.DB) error { return nil },
+			AfterDown:  func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+	observer := &recordingObserver{}
+
+	nb, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, migrate.Options{
+		UseTransaction: true,
+		Observer:       observer,
+	})
+	if err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	if nb != 1 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+
+	if want := []string{"before", "up", "after"}; len(calls) != len(want) ||
+		calls[0] != want[0] || calls[1] != want[1] || calls[2] != want[2] {
+		t.Fatalf("Wrong call order: %v", calls)
+	}
+
+	if len(observer.starts) != 1 || observer.starts[0] != "000" {
+		t.Fatalf("Wrong OnStepStart calls: %v", observer.starts)
+	}
+
+	if len(observer.ends) != 1 || observer.ends[0] != "000" {
+		t.Fatalf("Wrong OnStepEnd calls: %v", observer.ends)
+	}
+
+	if !observer.batchDone || observer.batchNb != 1 || observer.batchErr != nil {
+		t.Fatalf("Wrong OnBatchEnd call: nb=%d err=%v done=%v", observer.batchNb, observer.batchErr, observer.batchDone)
+	}
+}
+
+func TestStepHookFailureRollsBack(t *testing.T) {
+	errHook := errors.New("hook failed")
+
+	steps := migrate.Migrations{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return db.Migrator().AutoMigrate(&User{}) },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+		{
+			Name:     "001",
+			BeforeUp: func(db *gorm.DB) error { return errHook },
+			Up:       func(db *gorm.DB) error { return nil },
+			Down:     func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	if _, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, migrate.Options{UseTransaction: true}); err == nil {
+		t.Fatal("Should have failed: BeforeUp returned an error")
+	}
+
+	if db.Migrator().HasTable(&User{}) {
+		t.Fatal("The whole run should have been rolled back, including step 000")
+	}
+}
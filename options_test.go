@@ -0,0 +1,115 @@
+package migrate_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+func TestInitSchema(t *testing.T) {
+	nbUpCalls := 0
+
+	steps := migrate.Migrations{
+		{
+			Name: "000",
+			Up: func(db *gorm.DB) error {
+				nbUpCalls++
+
+				return db.Migrator().AutoMigrate(&User{})
+			},
+			Down: func(db *gorm.DB) error { return nil },
+		},
+		{
+			Name: "001",
+			Up: func(db *gorm.DB) error {
+				nbUpCalls++
+
+				return nil
+			},
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	options := migrate.Options{
+		UseTransaction: true,
+		InitSchema: func(db *gorm.DB) error {
+			return db.Migrator().AutoMigrate(&User{}, &Friend{})
+		},
+	}
+
+	nb, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, options)
+	if err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	if nb != len(steps) {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+
+	if nbUpCalls != 0 {
+		t.Fatalf("InitSchema should have skipped every step's Up method, but %d ran", nbUpCalls)
+	}
+
+	if !db.Migrator().HasTable(&Friend{}) {
+		t.Fatal("InitSchema should have created the Friend table")
+	}
+
+	// A second run should behave like a normal incremental Migrate: nothing left to apply.
+	if nb, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, options); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	} else if nb != 0 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+}
+
+func TestMigrateWithOptionsCustomTableName(t *testing.T) {
+	steps := migrate.Migrations{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	options := migrate.Options{TableName: "custom_migrations", UseTransaction: true}
+
+	if nb, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, options); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	} else if nb != 1 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+
+	if !db.Migrator().HasTable("custom_migrations") {
+		t.Fatal("Custom migrations table should have been created")
+	}
+
+	if db.Migrator().HasTable("gorm_migrations") {
+		t.Fatal("Default migrations table shouldn't have been created")
+	}
+}
+
+func TestMigrateWithOptionsValidateUnknownMigrations(t *testing.T) {
+	db := getDB(t)
+
+	if _, err := migrate.Migrate(db, migrate.Migrations{
+		{Name: "000", Up: func(db *gorm.DB) error { return nil }, Down: func(db *gorm.DB) error { return nil }},
+		{Name: "001", Up: func(db *gorm.DB) error { return nil }, Down: func(db *gorm.DB) error { return nil }},
+	}, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	// "001" is no longer known to the caller: this should be rejected.
+	_, err := migrate.MigrateWithOptions(db, migrate.Migrations{
+		{Name: "000", Up: func(db *gorm.DB) error { return nil }, Down: func(db *gorm.DB) error { return nil }},
+	}, migrate.UpFull, migrate.Options{UseTransaction: true, ValidateUnknownMigrations: true})
+
+	if err == nil {
+		t.Fatal("Should have failed: migration 001 is unknown")
+	}
+}
@@ -0,0 +1,364 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidSource is reported when a migration source file or directory can't be parsed.
+type ErrInvalidSource struct {
+	Path   string // Path of the file (or migration name) that couldn't be parsed
+	Reason string // Reason explains what went wrong
+}
+
+func (e *ErrInvalidSource) Error() string {
+	return fmt.Sprintf("invalid migration source %s: %s", e.Path, e.Reason)
+}
+
+var (
+	pairedSQLFileRE = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+	singleSQLFileRE = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+	sectionUpRE   = regexp.MustCompile(`^--\s*\+migrate\s+Up\b`)
+	sectionDownRE = regexp.MustCompile(`^--\s*\+migrate\s+Down\b`)
+
+	statementBeginRE = regexp.MustCompile(`^--\s*\+migrate\s+StatementBegin\b`)
+	statementEndRE   = regexp.MustCompile(`^--\s*\+migrate\s+StatementEnd\b`)
+)
+
+// sqlEntry holds the raw up/down SQL for a single migration name, before it's split into
+// statements. hasUp/hasDown track whether each side was actually found (as opposed to
+// left at its zero value), so a migration missing one direction can be rejected instead
+// of silently turning into a no-op Up or Down. prefix is the migration's numeric prefix,
+// kept separately from name so ordering doesn't depend on how many digits it was padded to.
+type sqlEntry struct {
+	name           string
+	prefix         uint64
+	up             string
+	down           string
+	hasUp, hasDown bool
+}
+
+// NewFSSource builds Migrations out of the SQL files found in dir, inside fsys.
+//
+// Two file layouts are supported: paired files such as 0001_create_users.up.sql /
+// 0001_create_users.down.sql, or a single 0001_create_users.sql file containing both
+// directions separated by "-- +migrate Up" / "-- +migrate Down" markers. Files are
+// ordered by their numeric prefix.
+func NewFSSource(fsys fs.FS, dir string) (Migrations, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read migration directory %s: %w", dir, err)
+	}
+
+	byName := map[string]*sqlEntry{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		filename := entry.Name()
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, filename))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read migration file %s: %w", filename, err)
+		}
+
+		switch {
+		case pairedSQLFileRE.MatchString(filename):
+			m := pairedSQLFileRE.FindStringSubmatch(filename)
+			name := m[1] + "_" + m[2]
+
+			prefix, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil {
+				return nil, &ErrInvalidSource{Path: filename, Reason: "numeric prefix out of range"}
+			}
+
+			e := byName[name]
+			if e == nil {
+				e = &sqlEntry{name: name, prefix: prefix}
+				byName[name] = e
+			}
+
+			if m[3] == "up" {
+				e.up, e.hasUp = string(content), true
+			} else {
+				e.down, e.hasDown = string(content), true
+			}
+		case singleSQLFileRE.MatchString(filename):
+			m := singleSQLFileRE.FindStringSubmatch(filename)
+			name := m[1] + "_" + m[2]
+
+			prefix, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil {
+				return nil, &ErrInvalidSource{Path: filename, Reason: "numeric prefix out of range"}
+			}
+
+			up, down, err := splitUpDownSections(string(content))
+			if err != nil {
+				return nil, &ErrInvalidSource{Path: filename, Reason: err.Error()}
+			}
+
+			byName[name] = &sqlEntry{name: name, prefix: prefix, up: up, down: down, hasUp: true, hasDown: true}
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := byName[names[i]], byName[names[j]]
+		if a.prefix != b.prefix {
+			return a.prefix < b.prefix
+		}
+
+		return a.name < b.name
+	})
+
+	for _, name := range names {
+		entry := byName[name]
+
+		switch {
+		case !entry.hasUp:
+			return nil, &ErrInvalidSource{Path: entry.name, Reason: "missing up migration"}
+		case !entry.hasDown:
+			return nil, &ErrInvalidSource{Path: entry.name, Reason: "missing down migration"}
+		}
+	}
+
+	steps := make(Migrations, 0, len(names))
+
+	for _, name := range names {
+		entry := byName[name]
+
+		upStatements, err := splitStatements(entry.up)
+		if err != nil {
+			return nil, &ErrInvalidSource{Path: name, Reason: err.Error()}
+		}
+
+		downStatements, err := splitStatements(entry.down)
+		if err != nil {
+			return nil, &ErrInvalidSource{Path: name, Reason: err.Error()}
+		}
+
+		steps = append(steps, &MigrationStep{
+			Name: entry.name,
+			Up:   execStatements(upStatements),
+			Down: execStatements(downStatements),
+		})
+	}
+
+	return steps, nil
+}
+
+// NewDirSource builds Migrations out of the SQL files found in dir, on the local filesystem.
+// It's a thin wrapper around NewFSSource using os.DirFS.
+func NewDirSource(dir string) (Migrations, error) {
+	return NewFSSource(os.DirFS(dir), ".")
+}
+
+// execStatements builds a MigrationMethod that runs every statement through db.Exec, in order.
+func execStatements(statements []string) MigrationMethod {
+	return func(db *gorm.DB) error {
+		for _, stmt := range statements {
+			if err := db.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("couldn't execute statement: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// splitUpDownSections splits a single SQL file's content along its "-- +migrate Up" and
+// "-- +migrate Down" markers.
+func splitUpDownSections(content string) (up string, down string, err error) {
+	var upBuf, downBuf strings.Builder
+
+	section := 0 // 0 = none yet, 1 = up, 2 = down
+	sawUp, sawDown := false, false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case sectionUpRE.MatchString(trimmed):
+			section = 1
+			sawUp = true
+
+			continue
+		case sectionDownRE.MatchString(trimmed):
+			section = 2
+			sawDown = true
+
+			continue
+		}
+
+		switch section {
+		case 1:
+			upBuf.WriteString(line)
+			upBuf.WriteByte('\n')
+		case 2:
+			downBuf.WriteString(line)
+			downBuf.WriteByte('\n')
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case !sawUp && !sawDown:
+		return "", "", fmt.Errorf(`missing "-- +migrate Up"/"-- +migrate Down" markers`)
+	case !sawUp:
+		return "", "", fmt.Errorf(`missing "-- +migrate Up" marker`)
+	case !sawDown:
+		return "", "", fmt.Errorf(`missing "-- +migrate Down" marker`)
+	}
+
+	return upBuf.String(), downBuf.String(), nil
+}
+
+// sqlScanner splits a stream of SQL into statements on unquoted, uncommented semicolons.
+type sqlScanner struct {
+	buf            strings.Builder
+	statements     []string
+	inSingleQuote  bool
+	inDoubleQuote  bool
+	inLineComment  bool
+	inBlockComment bool
+}
+
+func (s *sqlScanner) feed(text string) {
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case s.inLineComment:
+			s.buf.WriteRune(c)
+			if c == '\n' {
+				s.inLineComment = false
+			}
+		case s.inBlockComment:
+			s.buf.WriteRune(c)
+			if c == '*' && next == '/' {
+				s.buf.WriteRune(next)
+				i++
+				s.inBlockComment = false
+			}
+		case s.inSingleQuote:
+			s.buf.WriteRune(c)
+			if c == '\'' {
+				s.inSingleQuote = false
+			}
+		case s.inDoubleQuote:
+			s.buf.WriteRune(c)
+			if c == '"' {
+				s.inDoubleQuote = false
+			}
+		case c == '\'':
+			s.inSingleQuote = true
+			s.buf.WriteRune(c)
+		case c == '"':
+			s.inDoubleQuote = true
+			s.buf.WriteRune(c)
+		case c == '-' && next == '-':
+			s.inLineComment = true
+			s.buf.WriteRune(c)
+		case c == '/' && next == '*':
+			s.inBlockComment = true
+			s.buf.WriteRune(c)
+		case c == ';':
+			s.flush()
+		default:
+			s.buf.WriteRune(c)
+		}
+	}
+}
+
+func (s *sqlScanner) flush() {
+	if stmt := strings.TrimSpace(s.buf.String()); stmt != "" {
+		s.statements = append(s.statements, stmt)
+	}
+
+	s.buf.Reset()
+}
+
+// splitStatements splits a block of SQL into individual statements. It respects ';'
+// terminators while ignoring the ones found inside single/double-quoted strings or
+// "--"/"/* */" comments, and treats a "-- +migrate StatementBegin" / "StatementEnd"
+// section as a single statement, whatever semicolons it contains (needed for stored
+// procedures and triggers).
+func splitStatements(sql string) ([]string, error) {
+	scanner := &sqlScanner{}
+
+	inStatementBlock := false
+
+	var blockBuf strings.Builder
+
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case statementBeginRE.MatchString(trimmed):
+			if inStatementBlock {
+				return nil, fmt.Errorf(`nested "StatementBegin" marker`)
+			}
+
+			inStatementBlock = true
+			blockBuf.Reset()
+
+			continue
+		case statementEndRE.MatchString(trimmed):
+			if !inStatementBlock {
+				return nil, fmt.Errorf(`"StatementEnd" marker without matching "StatementBegin"`)
+			}
+
+			inStatementBlock = false
+			if stmt := strings.TrimSpace(blockBuf.String()); stmt != "" {
+				scanner.statements = append(scanner.statements, stmt)
+			}
+
+			continue
+		}
+
+		if inStatementBlock {
+			blockBuf.WriteString(line)
+			blockBuf.WriteByte('\n')
+
+			continue
+		}
+
+		scanner.feed(line + "\n")
+	}
+
+	if inStatementBlock {
+		return nil, fmt.Errorf(`unterminated "StatementBegin" block`)
+	}
+
+	scanner.flush()
+
+	return scanner.statements, nil
+}
@@ -0,0 +1,194 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+func TestNewFSSourcePaired(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id int);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/0002_add_age.up.sql":        {Data: []byte("ALTER TABLE users ADD COLUMN age int;")},
+		"migrations/0002_add_age.down.sql":      {Data: []byte("ALTER TABLE users DROP COLUMN age;")},
+	}
+
+	steps, err := migrate.NewFSSource(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Couldn't build source: %v", err)
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("Wrong number of steps: %d", len(steps))
+	}
+
+	if steps[0].Name != "0001_create_users" || steps[1].Name != "0002_add_age" {
+		t.Fatalf("Wrong step names: %s, %s", steps[0].Name, steps[1].Name)
+	}
+
+	db := getDB(t)
+
+	if nb, err := migrate.Migrate(db, steps, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	} else if nb != 2 {
+		t.Fatalf("Wrong number of applied migrations: %d", nb)
+	}
+
+	if !db.Migrator().HasColumn("users", "age") {
+		t.Fatal("Column age should have been created")
+	}
+}
+
+func TestNewFSSourceNumericPrefixOrder(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/1_first.up.sql":    {Data: []byte("CREATE TABLE a (id int);")},
+		"migrations/1_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"migrations/2_second.up.sql":   {Data: []byte("CREATE TABLE b (id int);")},
+		"migrations/2_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"migrations/10_tenth.up.sql":   {Data: []byte("CREATE TABLE c (id int);")},
+		"migrations/10_tenth.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+
+	steps, err := migrate.NewFSSource(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Couldn't build source: %v", err)
+	}
+
+	if len(steps) != 3 {
+		t.Fatalf("Wrong number of steps: %d", len(steps))
+	}
+
+	names := []string{steps[0].Name, steps[1].Name, steps[2].Name}
+	expected := []string{"1_first", "2_second", "10_tenth"}
+
+	for i, name := range names {
+		if name != expected[i] {
+			t.Fatalf("Wrong step order: got %v, expected %v", names, expected)
+		}
+	}
+}
+
+func TestNewFSSourceSingleFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.sql": {Data: []byte(
+			"-- +migrate Up\n" +
+				"CREATE TABLE users (id int);\n" +
+				"-- +migrate Down\n" +
+				"DROP TABLE users;\n",
+		)},
+	}
+
+	steps, err := migrate.NewFSSource(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Couldn't build source: %v", err)
+	}
+
+	if len(steps) != 1 {
+		t.Fatalf("Wrong number of steps: %d", len(steps))
+	}
+}
+
+func TestNewFSSourceQuotedSemicolon(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_notes.sql": {Data: []byte(
+			"-- +migrate Up\n" +
+				"CREATE TABLE notes (id int, body text);\n" +
+				"INSERT INTO notes (id, body) VALUES (1, 'hello; world');\n" +
+				"-- +migrate Down\n" +
+				"DROP TABLE notes;\n",
+		)},
+	}
+
+	steps, err := migrate.NewFSSource(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Couldn't build source: %v", err)
+	}
+
+	db := getDB(t)
+
+	if _, err := migrate.Migrate(db, steps, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	var body string
+	if err := db.Raw("SELECT body FROM notes WHERE id = 1").Scan(&body).Error; err != nil {
+		t.Fatalf("Couldn't read back the row: %v", err)
+	}
+
+	if body != "hello; world" {
+		t.Fatalf("Wrong body: %q", body)
+	}
+}
+
+func TestNewFSSourceStatementBlock(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_notes.sql": {Data: []byte(
+			"-- +migrate Up\n" +
+				"CREATE TABLE notes (id integer primary key, updated_at text);\n" +
+				"-- +migrate StatementBegin\n" +
+				"CREATE TRIGGER notes_updated AFTER UPDATE ON notes\n" +
+				"BEGIN\n" +
+				"  UPDATE notes SET updated_at = datetime('now') WHERE id = NEW.id;\n" +
+				"END;\n" +
+				"-- +migrate StatementEnd\n" +
+				"-- +migrate Down\n" +
+				"DROP TABLE notes;\n",
+		)},
+	}
+
+	steps, err := migrate.NewFSSource(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Couldn't build source: %v", err)
+	}
+
+	db := getDB(t)
+
+	if _, err := migrate.Migrate(db, steps, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+}
+
+func TestNewFSSourceMissingMarkers(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.sql": {Data: []byte("CREATE TABLE users (id int);\n")},
+	}
+
+	if _, err := migrate.NewFSSource(fsys, "migrations"); err == nil {
+		t.Fatal("Should have failed: no +migrate markers")
+	}
+}
+
+func TestNewFSSourceMissingDownMarker(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.sql": {Data: []byte(
+			"-- +migrate Up\n" +
+				"CREATE TABLE users (id int);\n",
+		)},
+	}
+
+	if _, err := migrate.NewFSSource(fsys, "migrations"); err == nil {
+		t.Fatal("Should have failed: no +migrate Down marker, would produce a no-op Down")
+	}
+}
+
+func TestNewFSSourcePairedMissingDownFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id int);")},
+	}
+
+	if _, err := migrate.NewFSSource(fsys, "migrations"); err == nil {
+		t.Fatal("Should have failed: no 0001_create_users.down.sql file, would produce a no-op Down")
+	}
+}
+
+func TestNewFSSourcePairedMissingUpFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	if _, err := migrate.NewFSSource(fsys, "migrations"); err == nil {
+		t.Fatal("Should have failed: no 0001_create_users.up.sql file")
+	}
+}
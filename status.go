@@ -0,0 +1,184 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StepStatus reports whether a given migration step is applied to the database.
+type StepStatus struct {
+	Name      string     // Name of the step
+	Applied   bool       // Applied indicates whether the step is applied
+	AppliedAt *time.Time // AppliedAt is when the step was applied, if it was
+	Known     bool       // Known indicates whether the step is present in the caller's steps
+}
+
+// PlannedStep describes a step that a subsequent Migrate call would run.
+type PlannedStep struct {
+	Name      string // Name of the step
+	Direction string // Direction is either "up" or "down"
+}
+
+// Status reports the state of every step, without touching the database: steps present in
+// steps but not yet applied, steps already applied, and steps found in the database but
+// missing from steps (Known == false). Status always includes the latter; use
+// StatusWithOptions with Options.IgnoreUnknown to omit them.
+func Status(db *gorm.DB, steps Migrations) ([]StepStatus, error) {
+	return StatusWithOptions(db, steps, Options{})
+}
+
+// StatusWithOptions behaves like Status, but lets the caller pick a custom migrations table
+// name and whether to ignore migrations unknown to the caller.
+func StatusWithOptions(db *gorm.DB, steps Migrations, options Options) ([]StepStatus, error) {
+	tableName := options.TableName
+	if tableName == "" {
+		tableName = defaultTableName
+	}
+
+	dbByName, err := loadAppliedMigrations(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(steps))
+	statuses := make([]StepStatus, 0, len(steps)+len(dbByName))
+
+	for _, step := range steps {
+		seen[step.Name] = true
+		statuses = append(statuses, stepStatus(step.Name, true, dbByName[step.Name]))
+	}
+
+	if !options.IgnoreUnknown {
+		for name, dbStep := range dbByName {
+			if seen[name] {
+				continue
+			}
+
+			statuses = append(statuses, stepStatus(name, false, dbStep))
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses, nil
+}
+
+func stepStatus(name string, known bool, dbStep *stepSave) StepStatus {
+	status := StepStatus{Name: name, Known: known}
+
+	if dbStep != nil && dbStep.MigrationTime != nil {
+		status.Applied = true
+		status.AppliedAt = dbStep.MigrationTime
+	}
+
+	return status
+}
+
+// loadAppliedMigrations reads every row of the migrations table, keyed by name, skipping
+// the synthetic InitSchema marker. It returns an empty map without error if the table
+// doesn't exist yet.
+func loadAppliedMigrations(db *gorm.DB, tableName string) (map[string]*stepSave, error) {
+	dbByName := map[string]*stepSave{}
+
+	if !db.Migrator().HasTable(tableName) {
+		return dbByName, nil
+	}
+
+	var dbSteps []stepSave
+	if err := db.Table(tableName).Find(&dbSteps).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range dbSteps {
+		if dbSteps[i].Name == schemaInitName {
+			continue
+		}
+
+		dbByName[dbSteps[i].Name] = &dbSteps[i]
+	}
+
+	return dbByName, nil
+}
+
+// Plan reports which steps a Migrate call would run for the given direction, without
+// touching the database.
+func Plan(db *gorm.DB, steps Migrations, direction int) ([]PlannedStep, error) {
+	return PlanWithOptions(db, steps, direction, Options{})
+}
+
+// PlanWithOptions behaves like Plan, but lets the caller pick a custom migrations table
+// name and hard-fail on migrations unknown to the caller. On a fresh database with
+// options.InitSchema set, it reports the single InitSchema fast-path step that
+// MigrateWithOptions would take instead of listing every step as an individual "up".
+func PlanWithOptions(db *gorm.DB, steps Migrations, direction int, options Options) ([]PlannedStep, error) {
+	if err := checkMigrations(steps); err != nil {
+		return nil, fmt.Errorf("bad migration: %w", err)
+	}
+
+	if direction == 0 {
+		return nil, ErrBadDirection
+	}
+
+	tableName := options.TableName
+	if tableName == "" {
+		tableName = defaultTableName
+	}
+
+	if options.ValidateUnknownMigrations && db.Migrator().HasTable(tableName) {
+		if err := validateKnownMigrations(db, tableName, steps); err != nil {
+			return nil, err
+		}
+	}
+
+	nbExisting := int64(0)
+
+	if db.Migrator().HasTable(tableName) {
+		var err error
+
+		nbExisting, err = countMigrations(db, tableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if nbExisting == 0 && options.InitSchema != nil && direction > 0 {
+		// Mirrors MigrateWithOptions: on a fresh database, InitSchema runs once instead of
+		// each step's Up method, so it's reported as a single planned step rather than one
+		// per migration.
+		return []PlannedStep{{Name: schemaInitName, Direction: "up"}}, nil
+	}
+
+	lastMigrationName := ""
+
+	if nbExisting > 0 {
+		lastMigration, err := getLastAppliedMigration(db, tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastMigration != nil {
+			lastMigrationName = lastMigration.Name
+		}
+	}
+
+	if lastMigrationName == "" && direction < 0 {
+		return nil, nil
+	}
+
+	directionLabel := "up"
+	if direction < 0 {
+		directionLabel = "down"
+	}
+
+	nextSteps := getSteps(steps, lastMigrationName, direction)
+	planned := make([]PlannedStep, 0, len(nextSteps))
+
+	for _, step := range nextSteps {
+		planned = append(planned, PlannedStep{Name: step.Name, Direction: directionLabel})
+	}
+
+	return planned, nil
+}
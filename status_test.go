@@ -0,0 +1,137 @@
+package migrate_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+func TestStatusAndPlan(t *testing.T) {
+	steps := []*migrate.MigrationStep{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+		{
+			Name: "001",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	if statuses, err := migrate.Status(db, steps); err != nil {
+		t.Fatalf("Couldn't get status: %v", err)
+	} else if len(statuses) != 2 || statuses[0].Applied || statuses[1].Applied {
+		t.Fatalf("Wrong initial status: %+v", statuses)
+	}
+
+	if planned, err := migrate.Plan(db, steps, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't plan: %v", err)
+	} else if len(planned) != 2 || planned[0].Direction != "up" {
+		t.Fatalf("Wrong plan: %+v", planned)
+	}
+
+	if _, err := migrate.Migrate(db, steps[0:1], migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	statuses, err := migrate.Status(db, steps)
+	if err != nil {
+		t.Fatalf("Couldn't get status: %v", err)
+	}
+
+	if !statuses[0].Applied || statuses[0].AppliedAt == nil {
+		t.Fatalf("Step 000 should be applied: %+v", statuses[0])
+	}
+
+	if statuses[1].Applied {
+		t.Fatalf("Step 001 shouldn't be applied: %+v", statuses[1])
+	}
+
+	planned, err := migrate.Plan(db, steps, migrate.UpFull)
+	if err != nil {
+		t.Fatalf("Couldn't plan: %v", err)
+	}
+
+	if len(planned) != 1 || planned[0].Name != "001" {
+		t.Fatalf("Wrong plan: %+v", planned)
+	}
+}
+
+func TestPlanWithOptionsInitSchema(t *testing.T) {
+	steps := migrate.Migrations{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+		{
+			Name: "001",
+			Up:   func(db *gorm.DB) error { return nil },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	options := migrate.Options{
+		InitSchema: func(db *gorm.DB) error { return nil },
+	}
+
+	planned, err := migrate.PlanWithOptions(db, steps, migrate.UpFull, options)
+	if err != nil {
+		t.Fatalf("Couldn't plan: %v", err)
+	}
+
+	if len(planned) != 1 || planned[0].Direction != "up" {
+		t.Fatalf("Plan should report the InitSchema fast-path as a single step, got: %+v", planned)
+	}
+
+	if _, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, options); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	// Once InitSchema has already run, Plan should fall back to the normal incremental view.
+	planned, err = migrate.PlanWithOptions(db, steps, migrate.UpFull, options)
+	if err != nil {
+		t.Fatalf("Couldn't plan: %v", err)
+	}
+
+	if len(planned) != 0 {
+		t.Fatalf("Nothing should be left to plan: %+v", planned)
+	}
+}
+
+func TestStatusUnknownMigration(t *testing.T) {
+	db := getDB(t)
+
+	if _, err := migrate.Migrate(db, []*migrate.MigrationStep{
+		{Name: "000", Up: func(db *gorm.DB) error { return nil }, Down: func(db *gorm.DB) error { return nil }},
+	}, migrate.UpFull); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	// "000" is no longer part of the caller's steps.
+	statuses, err := migrate.Status(db, nil)
+	if err != nil {
+		t.Fatalf("Couldn't get status: %v", err)
+	}
+
+	if len(statuses) != 1 || statuses[0].Known {
+		t.Fatalf("Step 000 should be reported as unknown: %+v", statuses)
+	}
+
+	statuses, err = migrate.StatusWithOptions(db, nil, migrate.Options{IgnoreUnknown: true})
+	if err != nil {
+		t.Fatalf("Couldn't get status: %v", err)
+	}
+
+	if len(statuses) != 0 {
+		t.Fatalf("Unknown step should have been ignored: %+v", statuses)
+	}
+}
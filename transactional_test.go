@@ -0,0 +1,84 @@
+package migrate_test
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	migrate "github.com/fclairamb/gorm-migrate"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestNonTransactionalStepKeepsPreviousStepsApplied(t *testing.T) {
+	errStep := errors.New("step 001 failed")
+
+	steps := migrate.Migrations{
+		{
+			Name: "000",
+			Up:   func(db *gorm.DB) error { return db.Migrator().AutoMigrate(&User{}) },
+			Down: func(db *gorm.DB) error { return nil },
+		},
+		{
+			Name:          "001",
+			Transactional: boolPtr(false),
+			Up:            func(db *gorm.DB) error { return errStep },
+			Down:          func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	if _, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, migrate.Options{
+		UseTransaction: false,
+	}); err == nil {
+		t.Fatal("Should have failed: step 001 returns an error")
+	}
+
+	if !db.Migrator().HasTable(&User{}) {
+		t.Fatal("Step 000 should stay applied even though step 001 failed")
+	}
+
+	statuses, err := migrate.Status(db, steps)
+	if err != nil {
+		t.Fatalf("Couldn't get status: %v", err)
+	}
+
+	if !statuses[0].Applied {
+		t.Fatal("Step 000 should be recorded as applied")
+	}
+
+	if statuses[1].Applied {
+		t.Fatal("Step 001 shouldn't be recorded as applied")
+	}
+}
+
+func TestNonTransactionalStepRunsOutsideTransaction(t *testing.T) {
+	var sawOpenTx bool
+
+	steps := migrate.Migrations{
+		{
+			Name:          "000",
+			Transactional: boolPtr(false),
+			Up: func(db *gorm.DB) error {
+				// A plain (non-savepoint) transaction exposes a *sql.Tx as its "current" connection.
+				sawOpenTx = db.Statement.ConnPool != db.Config.ConnPool
+				return nil
+			},
+			Down: func(db *gorm.DB) error { return nil },
+		},
+	}
+
+	db := getDB(t)
+
+	if _, err := migrate.MigrateWithOptions(db, steps, migrate.UpFull, migrate.Options{
+		UseTransaction: false,
+	}); err != nil {
+		t.Fatalf("Couldn't migrate: %v", err)
+	}
+
+	if sawOpenTx {
+		t.Fatal("The non-transactional step's Up shouldn't run inside a transaction")
+	}
+}